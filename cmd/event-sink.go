@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	ctlaws "github.com/liangrog/cfctl/pkg/aws"
+	"github.com/liangrog/cfctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CMD_STACK_EVENT_SINK = "event-sink"
+)
+
+// Event sink spec prefixes, as passed to --event-sink.
+const (
+	eventSinkJson    = "json"
+	eventSinkWebhook = "webhook"
+	eventSinkSns     = "sns"
+)
+
+// addFlagEventSink registers the repeatable --event-sink flag on cmd.
+// Only the stack diff command registers it; stack create/update/delete
+// do not take --event-sink.
+func addFlagEventSink(cmd *cobra.Command) {
+	cmd.Flags().StringArray(CMD_STACK_EVENT_SINK, []string{}, "Stream stack events to an additional sink. Can be repeated. One of: json, webhook=<url>, sns=<topic-arn>")
+}
+
+// buildEventSinks parses --event-sink specs into EventSinks, including
+// the console sink unless another sink already targets stdout (e.g.
+// json), since interleaving human-readable and machine-readable lines
+// on the same stream would break whichever one is meant to be piped.
+func buildEventSinks(cmd *cobra.Command, stackName, waiterType string) ([]ctlaws.EventSink, error) {
+	specs, err := cmd.Flags().GetStringArray(CMD_STACK_EVENT_SINK)
+	if err != nil {
+		return nil, err
+	}
+
+	var sinks []ctlaws.EventSink
+	if !hasStdoutEventSink(specs) {
+		sinks = append(sinks, ctlaws.NewConsoleEventSink(stackName, waiterType))
+	}
+
+	for _, spec := range specs {
+		kind, arg := spec, ""
+		if idx := strings.Index(spec, "="); idx >= 0 {
+			kind, arg = spec[:idx], spec[idx+1:]
+		}
+
+		switch kind {
+		case eventSinkJson:
+			sinks = append(sinks, ctlaws.NewJSONLinesEventSink(os.Stdout))
+		case eventSinkWebhook:
+			if arg == "" {
+				return nil, errors.New(utils.MsgFormat(fmt.Sprintf("Missing URL in --%s webhook=<url>", CMD_STACK_EVENT_SINK), utils.MessageTypeError))
+			}
+			sinks = append(sinks, ctlaws.NewWebhookEventSink(arg))
+		case eventSinkSns:
+			if arg == "" {
+				return nil, errors.New(utils.MsgFormat(fmt.Sprintf("Missing topic ARN in --%s sns=<topic-arn>", CMD_STACK_EVENT_SINK), utils.MessageTypeError))
+			}
+			sinks = append(sinks, ctlaws.NewSNSEventSink(ctlaws.AWSSess, arg))
+		default:
+			return nil, errors.New(utils.MsgFormat(fmt.Sprintf("Unknown --%s %q", CMD_STACK_EVENT_SINK, spec), utils.MessageTypeError))
+		}
+	}
+
+	return sinks, nil
+}
+
+// hasStdoutEventSink reports whether any of the given --event-sink specs
+// already writes to stdout, meaning the console sink must be left out
+// to avoid interleaving with it.
+func hasStdoutEventSink(specs []string) bool {
+	for _, spec := range specs {
+		kind := spec
+		if idx := strings.Index(spec, "="); idx >= 0 {
+			kind = spec[:idx]
+		}
+
+		if kind == eventSinkJson {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"github.com/liangrog/cfctl/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CMD_ROOT_LOG_FORMAT = "log-format"
+	CMD_ROOT_LOG_LEVEL  = "log-level"
+)
+
+// Register the global logging flags and wire them into pkg/log's
+// default Logger before any command runs.
+func init() {
+	CmdRoot.PersistentFlags().String(CMD_ROOT_LOG_FORMAT, "text", "Log output format, one of: text, json")
+	CmdRoot.PersistentFlags().String(CMD_ROOT_LOG_LEVEL, "info", "Minimum log level to emit, one of: debug, info, warn, error")
+
+	cobra.OnInitialize(initLogger)
+}
+
+func initLogger() {
+	format := CmdRoot.PersistentFlags().Lookup(CMD_ROOT_LOG_FORMAT).Value.String()
+	level := log.ParseLevel(CmdRoot.PersistentFlags().Lookup(CMD_ROOT_LOG_LEVEL).Value.String())
+
+	log.SetDefault(log.New(format, level))
+}
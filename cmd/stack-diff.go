@@ -0,0 +1,259 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	cf "github.com/aws/aws-sdk-go/service/cloudformation"
+	ctlaws "github.com/liangrog/cfctl/pkg/aws"
+	"github.com/liangrog/cfctl/pkg/log"
+	"github.com/liangrog/cfctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+// ANSI color codes for the change set action/drift markers.
+const (
+	colorReset  = "\033[0m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+)
+
+const (
+	CMD_STACK_DIFF_NAME     = "name"
+	CMD_STACK_DIFF_TEMPLATE = "template"
+	CMD_STACK_DIFF_PARAM    = "param"
+	CMD_STACK_DIFF_TAG      = "tag"
+	CMD_STACK_DIFF_EXECUTE  = "execute"
+)
+
+// Register sub commands
+func init() {
+	cmd := getCmdStackDiff()
+	addFlagsStackDiff(cmd)
+
+	CmdStack.AddCommand(cmd)
+}
+
+func addFlagsStackDiff(cmd *cobra.Command) {
+	cmd.Flags().StringP(CMD_STACK_DIFF_NAME, "n", "", "Stack name to preview changes for")
+	cmd.Flags().StringP(CMD_STACK_DIFF_TEMPLATE, "t", "", "Path to the local cloudformation template")
+	cmd.Flags().StringArrayP(CMD_STACK_DIFF_PARAM, "p", []string{}, "Stack parameter in the form key=value. Can be repeated")
+	cmd.Flags().StringArray(CMD_STACK_DIFF_TAG, []string{}, "Stack tag in the form key=value. Can be repeated")
+	cmd.Flags().Bool(CMD_STACK_DIFF_EXECUTE, false, "Execute the change set immediately after previewing it")
+	addFlagEventSink(cmd)
+	addFlagsTemplateBucket(cmd)
+
+	cmd.MarkFlagRequired(CMD_STACK_DIFF_NAME)
+	cmd.MarkFlagRequired(CMD_STACK_DIFF_TEMPLATE)
+}
+
+// cmd: diff
+func getCmdStackDiff() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Preview the changes a stack create/update would make",
+		Long: `Synthesize a CloudFormation change set from the local template,
+parameters and tags, and render the resource-level Adds/Modifies/Removes
+and parameter drift it would produce without applying anything. Pass
+--execute to apply the change set once it has been previewed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			params, err := parseKeyValueFlags(cmd, CMD_STACK_DIFF_PARAM)
+			if err != nil {
+				return err
+			}
+
+			tags, err := parseKeyValueFlags(cmd, CMD_STACK_DIFF_TAG)
+			if err != nil {
+				return err
+			}
+
+			execute, err := cmd.Flags().GetBool(CMD_STACK_DIFF_EXECUTE)
+			if err != nil {
+				return err
+			}
+
+			err = stackDiff(
+				cmd,
+				cmd.Flags().Lookup(CMD_STACK_DIFF_NAME).Value.String(),
+				cmd.Flags().Lookup(CMD_STACK_DIFF_TEMPLATE).Value.String(),
+				params,
+				tags,
+				execute,
+			)
+
+			silenceUsageOnError(cmd, err)
+
+			return err
+		},
+	}
+
+	return cmd
+}
+
+// Split "key=value" flag values into a map. Malformed entries are rejected.
+func parseKeyValueFlags(cmd *cobra.Command, flag string) (map[string]string, error) {
+	values, err := cmd.Flags().GetStringArray(flag)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]string, len(values))
+	for _, v := range values {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.New(utils.MsgFormat(fmt.Sprintf("Invalid --%s value %q, expected key=value", flag, v), utils.MessageTypeError))
+		}
+
+		out[parts[0]] = parts[1]
+	}
+
+	return out, nil
+}
+
+// Preview a stack's pending changes via a throwaway change set, optionally
+// executing it once the preview has been rendered.
+func stackDiff(cmd *cobra.Command, stackName, templatePath string, params, tags map[string]string, execute bool) error {
+	tpl, err := ioutil.ReadFile(templatePath)
+	if err != nil {
+		return err
+	}
+
+	uploader := templateUploaderFromFlags(cmd)
+	defer cleanupStaleTemplatesFromFlags(cmd, uploader)
+
+	stack := ctlaws.NewStack(cf.New(ctlaws.AWSSess)).SetTemplateUploader(uploader)
+
+	var previousParams []*cf.Parameter
+	changeSetType := ctlaws.ChangeSetTypeUpdate
+	if previousStack, err := stack.DescribeStack(stackName); err != nil {
+		changeSetType = ctlaws.ChangeSetTypeCreate
+	} else {
+		previousParams = previousStack.Parameters
+	}
+
+	changeSetName := fmt.Sprintf("cfctl-diff-%d", time.Now().Unix())
+
+	if _, err := stack.CreateChangeSet(stackName, changeSetName, changeSetType, params, tags, tpl, ""); err != nil {
+		return err
+	}
+
+	described, err := stack.WaitForChangeSet(stackName, changeSetName)
+	if err != nil {
+		// Best effort cleanup, the change set is unusable either way.
+		stack.DeleteChangeSet(stackName, changeSetName)
+		return err
+	}
+
+	// A FAILED change set with no real error means the stack already
+	// matches the desired state - the common case for an idempotent run.
+	if ctlaws.IsNoChangesChangeSet(aws.StringValue(described.StatusReason)) {
+		log.Info("No changes.")
+		return stack.DeleteChangeSet(stackName, changeSetName)
+	}
+
+	printChangeSet(described, previousParams)
+
+	if !execute {
+		return stack.DeleteChangeSet(stackName, changeSetName)
+	}
+
+	if _, err := stack.ExecuteChangeSet(stackName, changeSetName); err != nil {
+		return err
+	}
+
+	waiterType := StackWaiterTypeFor(changeSetType)
+
+	sinks, err := buildEventSinks(cmd, stackName, waiterType)
+	if err != nil {
+		return err
+	}
+
+	return stack.PollStackEvents(stackName, waiterType, sinks...)
+}
+
+// Map a change set type to the waiter type used to poll the resulting stack.
+func StackWaiterTypeFor(changeSetType string) string {
+	if changeSetType == ctlaws.ChangeSetTypeCreate {
+		return ctlaws.StackWaiterTypeCreate
+	}
+
+	return ctlaws.StackWaiterTypeUpdate
+}
+
+// Render a change set's resource changes and parameter drift to stdout,
+// colored when stdout is a TTY.
+func printChangeSet(described *cf.DescribeChangeSetOutput, previousParams []*cf.Parameter) {
+	for _, change := range described.Changes {
+		rc := change.ResourceChange
+		if rc == nil {
+			continue
+		}
+
+		action := colorForAction(*rc.Action)
+		replacement := ""
+		if rc.Replacement != nil && *rc.Replacement == cf.ReplacementTrue {
+			replacement = " (replacement)"
+		}
+
+		log.Info(fmt.Sprintf("%s %s%s", action, *rc.LogicalResourceId, replacement))
+	}
+
+	printParameterDrift(previousParams, described.Parameters)
+}
+
+// Render the parameters whose value would change as part of this
+// change set, i.e. the drift between the current stack and the
+// template/overrides being previewed.
+func printParameterDrift(previous, next []*cf.Parameter) {
+	previousValues := make(map[string]string, len(previous))
+	for _, p := range previous {
+		previousValues[aws.StringValue(p.ParameterKey)] = aws.StringValue(p.ParameterValue)
+	}
+
+	for _, p := range next {
+		key := aws.StringValue(p.ParameterKey)
+		newValue := aws.StringValue(p.ParameterValue)
+
+		oldValue, existed := previousValues[key]
+		if existed && oldValue == newValue {
+			continue
+		}
+
+		marker := colorize("~", colorYellow)
+		if !existed {
+			marker = colorize("+", colorGreen)
+		}
+
+		log.Info(fmt.Sprintf("%s param %s: %q -> %q", marker, key, oldValue, newValue))
+	}
+}
+
+// Color-code a change set action for terminal output.
+func colorForAction(action string) string {
+	switch action {
+	case cf.ChangeActionAdd:
+		return colorize("+ Add", colorGreen)
+	case cf.ChangeActionModify:
+		return colorize("~ Modify", colorYellow)
+	case cf.ChangeActionRemove:
+		return colorize("- Remove", colorRed)
+	default:
+		return action
+	}
+}
+
+// colorize wraps s in color when stdout is a TTY, and leaves it plain
+// otherwise (e.g. piped into a file or log collector).
+func colorize(s, color string) string {
+	if !log.IsTerminal(os.Stdout) {
+		return s
+	}
+
+	return color + s + colorReset
+}
@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+
+	"github.com/liangrog/cfctl/pkg/render"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CMD_STACK_RENDER_TEMPLATE = "template"
+	CMD_STACK_RENDER_VARS     = "vars"
+	CMD_STACK_RENDER_SET      = "set"
+	CMD_STACK_RENDER_OUT      = "out"
+)
+
+// Register sub commands
+func init() {
+	cmd := getCmdStackRender()
+	addFlagsStackRender(cmd)
+
+	CmdStack.AddCommand(cmd)
+}
+
+func addFlagsStackRender(cmd *cobra.Command) {
+	cmd.Flags().StringP(CMD_STACK_RENDER_TEMPLATE, "t", "", "Path to the cloudformation template to render")
+	cmd.Flags().StringArray(CMD_STACK_RENDER_VARS, []string{}, "Path to a YAML or JSON variable file. Can be repeated, later files win")
+	cmd.Flags().StringArray(CMD_STACK_RENDER_SET, []string{}, "Override a variable as key=value, dotted keys address nested maps. Can be repeated")
+	cmd.Flags().StringP(CMD_STACK_RENDER_OUT, "o", "", "Write the rendered template here instead of stdout")
+	cmd.Flags().String(CMD_VAULT_PASSWORD, "", "Vault password used to resolve {{ vault \"path\" }} values")
+	cmd.Flags().String(CMD_VAULT_PASSWORD_FILE, "", "File holding the vault password used to resolve {{ vault \"path\" }} values")
+
+	cmd.MarkFlagRequired(CMD_STACK_RENDER_TEMPLATE)
+}
+
+// cmd: render
+func getCmdStackRender() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Render a cloudformation template through cfctl's templating engine",
+		Long: `Pre-process a cloudformation template through a Go text/template
+engine before it's validated or deployed. Variables can come from one or
+more --vars files (YAML/JSON, later files win), individual --set
+overrides, and an {{ vault "path/to/file" }} function that transparently
+decrypts ansible-vault encrypted values inline.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			err := stackRender(
+				cmd.Flags().Lookup(CMD_STACK_RENDER_TEMPLATE).Value.String(),
+				mustGetStringArray(cmd, CMD_STACK_RENDER_VARS),
+				mustGetStringArray(cmd, CMD_STACK_RENDER_SET),
+				cmd.Flags().Lookup(CMD_VAULT_PASSWORD).Value.String(),
+				cmd.Flags().Lookup(CMD_VAULT_PASSWORD_FILE).Value.String(),
+				cmd.Flags().Lookup(CMD_STACK_RENDER_OUT).Value.String(),
+			)
+
+			silenceUsageOnError(cmd, err)
+
+			return err
+		},
+	}
+
+	return cmd
+}
+
+// mustGetStringArray is a thin wrapper since these flags are always
+// registered by addFlagsStackRender and can't fail to parse.
+func mustGetStringArray(cmd *cobra.Command, flag string) []string {
+	values, _ := cmd.Flags().GetStringArray(flag)
+	return values
+}
+
+// Render a template to stdout or a file.
+func stackRender(templatePath string, varsFiles, sets []string, vaultPass, vaultPassFile, out string) error {
+	vars := make(map[string]interface{})
+
+	for _, varsFile := range varsFiles {
+		fileVars, err := render.LoadVarsFile(varsFile)
+		if err != nil {
+			return err
+		}
+
+		vars = render.Merge(vars, fileVars)
+	}
+
+	setVars, err := render.ParseSetFlags(sets)
+	if err != nil {
+		return err
+	}
+
+	vars = render.Merge(vars, setVars)
+
+	engine := render.NewEngine(vars, vaultPass, vaultPassFile)
+
+	output, err := engine.Render(templatePath)
+	if err != nil {
+		return err
+	}
+
+	if len(out) == 0 {
+		_, err := os.Stdout.Write(output)
+		return err
+	}
+
+	return ioutil.WriteFile(out, output, 0644)
+}
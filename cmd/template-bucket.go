@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+
+	ctlaws "github.com/liangrog/cfctl/pkg/aws"
+	"github.com/liangrog/cfctl/pkg/log"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CMD_TEMPLATE_BUCKET        = "template-bucket"
+	CMD_TEMPLATE_BUCKET_PREFIX = "template-bucket-prefix"
+	CMD_TEMPLATE_BUCKET_KMS    = "template-bucket-kms-key"
+	CMD_TEMPLATE_BUCKET_TTL    = "template-bucket-ttl"
+)
+
+// addFlagsTemplateBucket registers the --template-bucket* flags on cmd.
+// Only the stack diff command registers them; stack create/update do not
+// take --template-bucket*.
+func addFlagsTemplateBucket(cmd *cobra.Command) {
+	cmd.Flags().String(CMD_TEMPLATE_BUCKET, "", "S3 bucket to transparently upload templates over the inline size limit to")
+	cmd.Flags().String(CMD_TEMPLATE_BUCKET_PREFIX, "", "Key prefix to upload oversized templates under")
+	cmd.Flags().String(CMD_TEMPLATE_BUCKET_KMS, "", "KMS key id to encrypt uploaded templates with (SSE-KMS). Defaults to SSE-S3")
+	cmd.Flags().Duration(CMD_TEMPLATE_BUCKET_TTL, 0, "Delete uploaded templates older than this on every run, e.g. 24h. Disabled when 0")
+}
+
+// templateUploaderFromFlags builds a TemplateUploader from --template-bucket*
+// flags, or nil when no bucket was given - oversized templates then fail
+// ValidateTemplate as before.
+func templateUploaderFromFlags(cmd *cobra.Command) *ctlaws.TemplateUploader {
+	bucket := cmd.Flags().Lookup(CMD_TEMPLATE_BUCKET).Value.String()
+	if len(bucket) == 0 {
+		return nil
+	}
+
+	prefix := cmd.Flags().Lookup(CMD_TEMPLATE_BUCKET_PREFIX).Value.String()
+	kmsKeyId := cmd.Flags().Lookup(CMD_TEMPLATE_BUCKET_KMS).Value.String()
+
+	return ctlaws.NewTemplateUploader(ctlaws.AWSSess, bucket, prefix, kmsKeyId)
+}
+
+// cleanupStaleTemplatesFromFlags runs the uploader's TTL-based cleanup when
+// --template-bucket-ttl was given. Failures are logged as a warning rather
+// than returned, since a cleanup miss shouldn't fail the stack operation
+// that triggered it.
+func cleanupStaleTemplatesFromFlags(cmd *cobra.Command, uploader *ctlaws.TemplateUploader) {
+	if uploader == nil {
+		return
+	}
+
+	ttl, err := cmd.Flags().GetDuration(CMD_TEMPLATE_BUCKET_TTL)
+	if err != nil || ttl <= 0 {
+		return
+	}
+
+	if err := uploader.CleanupStale(ttl); err != nil {
+		log.Warn(fmt.Sprintf("Failed to clean up stale templates in %s: %s", uploader.Bucket, err))
+	}
+}
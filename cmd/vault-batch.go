@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"runtime"
+	"sync"
+
+	"github.com/liangrog/cfctl/pkg/log"
+	"github.com/liangrog/cfctl/pkg/utils"
+	"github.com/spf13/cobra"
+)
+
+const (
+	CMD_VAULT_PARALLELISM = "parallelism"
+)
+
+// addFlagParallelism registers the --parallelism flag shared by the vault
+// encrypt/decrypt commands.
+func addFlagParallelism(cmd *cobra.Command) {
+	cmd.Flags().Int(CMD_VAULT_PARALLELISM, runtime.NumCPU(), "Max number of files to process concurrently")
+}
+
+// fileJobResult is the per-file outcome of a batchRun job.
+type fileJobResult struct {
+	file string
+	err  error
+}
+
+// batchRun fans `files` out across a bounded worker pool of `parallelism`
+// goroutines, invoking fn once per file, and returns a summary plus an
+// aggregated error covering every file that failed.
+func batchRun(parallelism int, files []string, fn func(file string) error) (utils.BatchResult, error) {
+	if parallelism <= 0 {
+		parallelism = runtime.NumCPU()
+	}
+
+	jobs := make(chan string)
+	results := make(chan fileJobResult, len(files))
+
+	var wg sync.WaitGroup
+	for i := 0; i < parallelism; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range jobs {
+				results <- fileJobResult{file: file, err: fn(file)}
+			}
+		}()
+	}
+
+	go func() {
+		for _, file := range files {
+			jobs <- file
+		}
+		close(jobs)
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var result utils.BatchResult
+	var errs []error
+	for r := range results {
+		if r.err != nil {
+			result.Failed++
+			errs = append(errs, fmt.Errorf("%s: %w", r.file, r.err))
+		} else {
+			result.Succeeded++
+		}
+	}
+
+	log.Info(fmt.Sprintf("Batch complete: %s", result))
+
+	return result, utils.Combine(errs...)
+}
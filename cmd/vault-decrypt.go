@@ -32,6 +32,7 @@ var (
 // Register sub commands
 func init() {
 	cmd := getCmdVaultDecrypt()
+	addFlagParallelism(cmd)
 
 	CmdVault.AddCommand(cmd)
 }
@@ -50,10 +51,16 @@ func getCmdVaultDecrypt() *cobra.Command {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			err := batchDecrypt(
+			parallelism, err := cmd.Flags().GetInt(CMD_VAULT_PARALLELISM)
+			if err != nil {
+				return err
+			}
+
+			err = batchDecrypt(
 				cmd.Flags().Lookup(CMD_VAULT_PASSWORD).Value.String(),
 				cmd.Flags().Lookup(CMD_VAULT_PASSWORD_FILE).Value.String(),
 				args,
+				parallelism,
 			)
 
 			silenceUsageOnError(cmd, err)
@@ -66,52 +73,35 @@ func getCmdVaultDecrypt() *cobra.Command {
 	return cmd
 }
 
-func batchDecrypt(pss, pssFile string, files []string) error {
+func batchDecrypt(pss, pssFile string, files []string, parallelism int) error {
 	passwords, err := GetPasswords(pss, pssFile, false, false)
 	if err != nil {
 		return err
 	}
 
-	result := make(chan error, 10)
-	for _, file := range files {
-		go func(file string, pass []string, res chan<- error) {
-			data, err := ioutil.ReadFile(file)
-			if err != nil {
-				res <- err
-				return
-			}
-
-			// Try every given password
-			decrypted := false
-			var output []byte
-			for _, p := range pass {
-				output, err = vault.Decrypt(p, data)
-				if err == nil {
-					decrypted = true
-					break
-				}
-			}
+	_, err = batchRun(parallelism, files, func(file string) error {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
 
-			if decrypted && len(output) > 0 {
-				if err := ioutil.WriteFile(file, output, 0644); err != nil {
-					res <- err
-				}
-			} else {
-				res <- errors.New(fmt.Sprintf("Failed to decrypt %s using all given password", file))
+		// Try every given password
+		decrypted := false
+		var output []byte
+		for _, p := range passwords {
+			output, err = vault.Decrypt(p, data)
+			if err == nil {
+				decrypted = true
+				break
 			}
+		}
 
-			res <- nil
-		}(file, passwords, result)
-	}
-
-	for j := 0; j < len(files); j++ {
-		err := <-result
-		if err != nil {
-			if err := utils.Print("", files[j], err); err != nil {
-				return err
-			}
+		if !decrypted || len(output) == 0 {
+			return errors.New(fmt.Sprintf("Failed to decrypt %s using all given password", file))
 		}
-	}
 
-	return nil
+		return utils.WriteFileAtomic(file, output, 0644)
+	})
+
+	return err
 }
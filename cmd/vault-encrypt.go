@@ -36,6 +36,7 @@ var (
 // Register sub commands
 func init() {
 	cmd := getCmdVaultEncrypt()
+	addFlagParallelism(cmd)
 
 	CmdVault.AddCommand(cmd)
 }
@@ -54,10 +55,16 @@ func getCmdVaultEncrypt() *cobra.Command {
 			return nil
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
-			err := batchEncrypt(
+			parallelism, err := cmd.Flags().GetInt(CMD_VAULT_PARALLELISM)
+			if err != nil {
+				return err
+			}
+
+			err = batchEncrypt(
 				cmd.Flags().Lookup(CMD_VAULT_PASSWORD).Value.String(),
 				cmd.Flags().Lookup(CMD_VAULT_PASSWORD_FILE).Value.String(),
 				args,
+				parallelism,
 			)
 
 			silenceUsageOnError(cmd, err)
@@ -70,7 +77,7 @@ func getCmdVaultEncrypt() *cobra.Command {
 	return cmd
 }
 
-func batchEncrypt(pss, pssFile string, files []string) error {
+func batchEncrypt(pss, pssFile string, files []string, parallelism int) error {
 	passwords, err := GetPasswords(pss, pssFile, false, false)
 	if err != nil {
 		return err
@@ -81,37 +88,19 @@ func batchEncrypt(pss, pssFile string, files []string) error {
 		return errors.New("More than one passwords were given")
 	}
 
-	result := make(chan error, 10)
-	for _, file := range files {
-		go func(file, pass string, res chan<- error) {
-			data, err := ioutil.ReadFile(file)
-			if err != nil {
-				res <- err
-				return
-			}
-
-			output, err := vault.Encrypt(data, pass)
-			if err != nil {
-				res <- err
-				return
-			}
-
-			if err := ioutil.WriteFile(file, output, 0644); err != nil {
-				res <- err
-			}
-
-			res <- nil
-		}(file, passwords[0], result)
-	}
+	_, err = batchRun(parallelism, files, func(file string) error {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return err
+		}
 
-	for j := 0; j < len(files); j++ {
-		err := <-result
+		output, err := vault.Encrypt(data, passwords[0])
 		if err != nil {
-			if err := utils.Print("", files[j], err); err != nil {
-				return err
-			}
+			return err
 		}
-	}
 
-	return nil
+		return utils.WriteFileAtomic(file, output, 0644)
+	})
+
+	return err
 }
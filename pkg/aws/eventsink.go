@@ -0,0 +1,153 @@
+package aws
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	cf "github.com/aws/aws-sdk-go/service/cloudformation"
+	"github.com/aws/aws-sdk-go/service/sns"
+	"github.com/liangrog/cfctl/pkg/log"
+)
+
+// EventSink receives stack events as PollStackEvents observes them,
+// decoupling dispatch from how (or whether) they end up on stdout.
+type EventSink interface {
+	// OnEvent is called once per new stack event, in chronological order.
+	OnEvent(event *cf.StackEvent)
+
+	// OnComplete is called exactly once when polling stops, with the
+	// final waiter error (nil on success).
+	OnComplete(err error)
+}
+
+// ConsoleEventSink logs each event at info level through pkg/log, using
+// the same tab-separated line format PollStackEvents printed before
+// sinks existed. The log handler adds its level prefix, or encodes the
+// line as JSON under --log-format=json.
+type ConsoleEventSink struct {
+	stackName  string
+	waiterType string
+}
+
+// ConsoleEventSink constructor
+func NewConsoleEventSink(stackName, waiterType string) *ConsoleEventSink {
+	return &ConsoleEventSink{stackName: stackName, waiterType: waiterType}
+}
+
+func (c *ConsoleEventSink) OnEvent(evnt *cf.StackEvent) {
+	outStr := fmt.Sprintf(
+		"[ stack | %s ] %s\t%s\t%s\t%s",
+		c.waiterType,
+		c.stackName,
+		(*evnt.Timestamp).Format(time.RFC3339),
+		*evnt.LogicalResourceId,
+		*evnt.ResourceStatus,
+	)
+
+	// Not all records have reason.
+	if evnt.ResourceStatusReason != nil {
+		outStr += fmt.Sprintf("\t%s", *evnt.ResourceStatusReason)
+	}
+
+	log.Info(outStr)
+}
+
+func (c *ConsoleEventSink) OnComplete(err error) {}
+
+// JSONLinesEventSink writes one JSON-encoded event per line to w, for
+// piping into log shippers or other machine consumers.
+type JSONLinesEventSink struct {
+	w io.Writer
+}
+
+// JSONLinesEventSink constructor
+func NewJSONLinesEventSink(w io.Writer) *JSONLinesEventSink {
+	return &JSONLinesEventSink{w: w}
+}
+
+func (j *JSONLinesEventSink) OnEvent(evnt *cf.StackEvent) {
+	line, err := json.Marshal(evnt)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintln(j.w, string(line))
+}
+
+func (j *JSONLinesEventSink) OnComplete(err error) {}
+
+// WebhookEventSink POSTs each event as JSON to a URL, retrying with
+// exponential backoff on transport errors or 5xx responses.
+type WebhookEventSink struct {
+	url     string
+	client  *http.Client
+	retries int
+}
+
+// WebhookEventSink constructor
+func NewWebhookEventSink(url string) *WebhookEventSink {
+	return &WebhookEventSink{
+		url:     url,
+		client:  &http.Client{Timeout: 10 * time.Second},
+		retries: 3,
+	}
+}
+
+func (w *WebhookEventSink) OnEvent(evnt *cf.StackEvent) {
+	body, err := json.Marshal(evnt)
+	if err != nil {
+		return
+	}
+
+	backoff := 500 * time.Millisecond
+	for attempt := 0; attempt <= w.retries; attempt++ {
+		resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode < 500 {
+				return
+			}
+		}
+
+		if attempt == w.retries {
+			log.Warn(fmt.Sprintf("Failed to deliver stack event webhook to %s after %d attempts", w.url, w.retries+1))
+			return
+		}
+
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+func (w *WebhookEventSink) OnComplete(err error) {}
+
+// SNSEventSink publishes each event as JSON to an SNS topic, using the
+// same AWS session as the rest of cfctl.
+type SNSEventSink struct {
+	client   *sns.SNS
+	topicArn string
+}
+
+// SNSEventSink constructor
+func NewSNSEventSink(sess *session.Session, topicArn string) *SNSEventSink {
+	return &SNSEventSink{client: sns.New(sess), topicArn: topicArn}
+}
+
+func (s *SNSEventSink) OnEvent(evnt *cf.StackEvent) {
+	body, err := json.Marshal(evnt)
+	if err != nil {
+		return
+	}
+
+	input := new(sns.PublishInput).SetTopicArn(s.topicArn).SetMessage(string(body))
+	if _, err := s.client.Publish(input); err != nil {
+		log.Warn(fmt.Sprintf("Failed to publish stack event to SNS topic %s: %s", s.topicArn, err))
+	}
+}
+
+func (s *SNSEventSink) OnComplete(err error) {}
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
@@ -21,6 +22,10 @@ const (
 // Provide API testing stub
 type Stack struct {
 	Client cloudformationiface.CloudFormationAPI
+
+	// Optional. When set, templates exceeding maxTemplateLength are
+	// transparently uploaded to S3 instead of failing ValidateTemplate.
+	Uploader *TemplateUploader
 }
 
 // Stack constructor
@@ -28,6 +33,29 @@ func NewStack(cfapi cloudformationiface.CloudFormationAPI) *Stack {
 	return &Stack{Client: cfapi}
 }
 
+// Attach a TemplateUploader for oversized templates. Returns s for chaining.
+func (s *Stack) SetTemplateUploader(uploader *TemplateUploader) *Stack {
+	s.Uploader = uploader
+	return s
+}
+
+// resolveTemplate swaps an oversized inline template body for an S3
+// TemplateURL when a TemplateUploader has been configured. Returns tpl/url
+// unchanged otherwise, including when the template is oversized and no
+// uploader is configured - ValidateTemplate is left to reject that case.
+func (s *Stack) resolveTemplate(tpl []byte, url string) ([]byte, string, error) {
+	if len(tpl) > maxTemplateLength && s.Uploader != nil {
+		uploadedURL, err := s.Uploader.Upload(tpl)
+		if err != nil {
+			return nil, "", err
+		}
+
+		return nil, uploadedURL, nil
+	}
+
+	return tpl, url, nil
+}
+
 // List all stacks. Aggregate all pages and output only one array
 func (s *Stack) ListStacks(format string, statusFilter ...string) ([]*cf.StackSummary, error) {
 	var nextToken *string
@@ -70,19 +98,28 @@ func (s *Stack) ListStacks(format string, statusFilter ...string) ([]*cf.StackSu
 //
 // url must be in AWS s3 URL. See https://docs.aws.amazon.com/sdk-for-go/api/service/cloudformation/#ValidateTemplateInput
 //
-func (s *Stack) ValidateTemplate(tpl []byte, url string) (*cf.ValidateTemplateOutput, error) {
+// ValidateTemplate is the one place an oversized tpl gets resolved to an
+// uploaded url (via resolveTemplate): it returns the resolved tpl/url
+// alongside the validation output so CreateStack/UpdateStack/
+// CreateChangeSet can reuse them instead of resolving a second time.
+func (s *Stack) ValidateTemplate(tpl []byte, url string) ([]byte, string, *cf.ValidateTemplateOutput, error) {
 	var input *cf.ValidateTemplateInput
 	var output *cf.ValidateTemplateOutput
 
+	tpl, url, err := s.resolveTemplate(tpl, url)
+	if err != nil {
+		return tpl, url, output, err
+	}
+
 	// Must have one valide
 	if len(tpl) == 0 && len(url) == 0 {
-		return output, errors.New(utils.MsgFormat("Missing cloudformation template or template URLs", utils.MessageTypeError))
+		return tpl, url, output, errors.New(utils.MsgFormat("Missing cloudformation template or template URLs", utils.MessageTypeError))
 	}
 
 	// If template string is given
 	if len(tpl) > 0 {
 		if len(tpl) > maxTemplateLength {
-			return output, errors.New(utils.MsgFormat(fmt.Sprintf("Exceeded maximum template size of %d bytes", maxTemplateLength), utils.MessageTypeError))
+			return tpl, url, output, errors.New(utils.MsgFormat(fmt.Sprintf("Exceeded maximum template size of %d bytes", maxTemplateLength), utils.MessageTypeError))
 		}
 
 		input = &cf.ValidateTemplateInput{
@@ -98,7 +135,9 @@ func (s *Stack) ValidateTemplate(tpl []byte, url string) (*cf.ValidateTemplateOu
 
 	}
 
-	return s.Client.ValidateTemplate(input)
+	output, err = s.Client.ValidateTemplate(input)
+
+	return tpl, url, output, err
 }
 
 // Convert tags from map to Tag slice
@@ -125,8 +164,8 @@ func (s *Stack) ParamSlice(params map[string]string) []*cf.Parameter {
 func (s *Stack) CreateStack(name string, params map[string]string, tags map[string]string, tpl []byte, url string) (*cf.CreateStackOutput, error) {
 	var stackOutput *cf.CreateStackOutput
 
-	// Validate template
-	valid, err := s.ValidateTemplate(tpl, url)
+	// Validate template, which also resolves an oversized tpl to url
+	tpl, url, valid, err := s.ValidateTemplate(tpl, url)
 	if err != nil {
 		return stackOutput, err
 	}
@@ -153,8 +192,8 @@ func (s *Stack) CreateStack(name string, params map[string]string, tags map[stri
 func (s *Stack) UpdateStack(name string, params map[string]string, tags map[string]string, tpl []byte, url string) (*cf.UpdateStackOutput, error) {
 	var output *cf.UpdateStackOutput
 
-	// Validate template
-	Valid, err := s.ValidateTemplate(tpl, url)
+	// Validate template, which also resolves an oversized tpl to url
+	tpl, url, Valid, err := s.ValidateTemplate(tpl, url)
 	if err != nil {
 		return output, err
 	}
@@ -177,6 +216,116 @@ func (s *Stack) UpdateStack(name string, params map[string]string, tags map[stri
 	return s.Client.UpdateStack(input)
 }
 
+const (
+	// Change set type "create". Use when the target stack doesn't exist yet.
+	ChangeSetTypeCreate = "CREATE"
+
+	// Change set type "update". Use when the target stack already exists.
+	ChangeSetTypeUpdate = "UPDATE"
+)
+
+// Create a change set previewing what a stack create/update would do.
+//
+// changeSetType must be one of ChangeSetTypeCreate/ChangeSetTypeUpdate.
+func (s *Stack) CreateChangeSet(name, changeSetName, changeSetType string, params map[string]string, tags map[string]string, tpl []byte, url string) (*cf.CreateChangeSetOutput, error) {
+	var output *cf.CreateChangeSetOutput
+
+	// Validate template, which also resolves an oversized tpl to url
+	tpl, url, valid, err := s.ValidateTemplate(tpl, url)
+	if err != nil {
+		return output, err
+	}
+
+	tags = tagPkgStamp(tags)
+
+	input := new(cf.CreateChangeSetInput).
+		SetStackName(name).
+		SetChangeSetName(changeSetName).
+		SetChangeSetType(changeSetType).
+		SetParameters(s.ParamSlice(params)).
+		SetCapabilities(valid.Capabilities).
+		SetTags(s.TagSlice(tags))
+
+	// Template
+	if len(tpl) > 0 {
+		input.SetTemplateBody(string(tpl))
+	} else {
+		input.SetTemplateURL(url)
+	}
+
+	return s.Client.CreateChangeSet(input)
+}
+
+// Describe a change set by stack and change set name
+func (s *Stack) DescribeChangeSet(stackName, changeSetName string) (*cf.DescribeChangeSetOutput, error) {
+	input := new(cf.DescribeChangeSetInput).
+		SetStackName(stackName).
+		SetChangeSetName(changeSetName)
+
+	return s.Client.DescribeChangeSet(input)
+}
+
+// Poll a change set until it reaches a terminal status, returning the final describe output.
+func (s *Stack) WaitForChangeSet(stackName, changeSetName string) (*cf.DescribeChangeSetOutput, error) {
+	for {
+		output, err := s.DescribeChangeSet(stackName, changeSetName)
+		if err != nil {
+			return output, err
+		}
+
+		switch aws.StringValue(output.Status) {
+		case cf.ChangeSetStatusCreateComplete:
+			return output, nil
+		case cf.ChangeSetStatusFailed:
+			if IsNoChangesChangeSet(aws.StringValue(output.StatusReason)) {
+				return output, nil
+			}
+			return output, errors.New(utils.MsgFormat(fmt.Sprintf("Change set %s failed: %s", changeSetName, aws.StringValue(output.StatusReason)), utils.MessageTypeError))
+		}
+
+		time.Sleep(time.Second)
+	}
+}
+
+// noChangesStatusReasons are the StatusReason substrings CloudFormation
+// uses when a change set fails not because synthesis went wrong, but
+// because the stack already matches the desired state - the common
+// case for an idempotent "stack diff" against an unchanged stack.
+var noChangesStatusReasons = []string{
+	"No updates are to be performed",
+	"didn't contain changes",
+}
+
+// IsNoChangesChangeSet reports whether a FAILED change set's reason
+// means "nothing to do" rather than a real synthesis failure.
+func IsNoChangesChangeSet(reason string) bool {
+	for _, s := range noChangesStatusReasons {
+		if strings.Contains(reason, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Execute a previously created change set
+func (s *Stack) ExecuteChangeSet(stackName, changeSetName string) (*cf.ExecuteChangeSetOutput, error) {
+	input := new(cf.ExecuteChangeSetInput).
+		SetStackName(stackName).
+		SetChangeSetName(changeSetName)
+
+	return s.Client.ExecuteChangeSet(input)
+}
+
+// Delete a change set
+func (s *Stack) DeleteChangeSet(stackName, changeSetName string) (*cf.DeleteChangeSetOutput, error) {
+	input := new(cf.DeleteChangeSetInput).
+		SetStackName(stackName).
+		SetChangeSetName(changeSetName)
+
+	return s.Client.DeleteChangeSet(input)
+}
+
 // Delete a stack
 func (s *Stack) DeleteStack(stackName string, retainResc ...string) (*cf.DeleteStackOutput, error) {
 	input := new(cf.DeleteStackInput).
@@ -344,8 +493,14 @@ const (
 	StackWaiterTypeDelete = "delete"
 )
 
-// Poll stack events and print them out in console.
-func (s *Stack) PollStackEvents(stackName, waiterType string) error {
+// Poll stack events and dispatch them to the given sinks. Defaults to a
+// ConsoleEventSink, preserving the original stdout-printing behaviour,
+// when no sinks are given.
+func (s *Stack) PollStackEvents(stackName, waiterType string, sinks ...EventSink) error {
+	if len(sinks) == 0 {
+		sinks = []EventSink{NewConsoleEventSink(stackName, waiterType)}
+	}
+
 	// Stop signal from waiter.
 	stop := make(chan error)
 
@@ -371,13 +526,14 @@ func (s *Stack) PollStackEvents(stackName, waiterType string) error {
 	}()
 
 	for {
-		// Fetch stack event and print it out.
+		// Fetch stack events and dispatch them to every sink.
 		if events, err := s.GetStackEvents(stackName, timestamp); err != nil {
 			//ignore validation error due to stack doesn't exist
 			//during delete since the stack has been deleted
 			awsErr, ok := err.(awserr.Error)
 			if (waiterType != StackWaiterTypeDelete || !ok) &&
 				awsErr.Code() != "ValidationError" {
+				dispatchComplete(sinks, err)
 				return err
 			}
 		} else if len(events) > 0 {
@@ -385,23 +541,10 @@ func (s *Stack) PollStackEvents(stackName, waiterType string) error {
 
 			for _, evnt := range events {
 				if timestamp.Before(*evnt.Timestamp) {
-					// Printing stack events
-					outStr := fmt.Sprintf(
-						"[ stack | %s ] %s\t%s\t%s\t%s",
-						waiterType,
-						stackName,
-						(*evnt.Timestamp).Format(time.RFC3339),
-						*evnt.LogicalResourceId,
-						*evnt.ResourceStatus,
-					)
-
-					// Not all records have reason.
-					if evnt.ResourceStatusReason != nil {
-						outStr += fmt.Sprintf("\t%s", *evnt.ResourceStatusReason)
+					for _, sink := range sinks {
+						sink.OnEvent(evnt)
 					}
 
-					utils.InfoPrint(outStr)
-
 					// Update to the newer event timestamp.
 					if tmpTime.Before(*evnt.Timestamp) {
 						tmpTime = *evnt.Timestamp
@@ -416,6 +559,7 @@ func (s *Stack) PollStackEvents(stackName, waiterType string) error {
 		select {
 		// Exit if the wait is over
 		case err := <-stop:
+			dispatchComplete(sinks, err)
 			return err
 		default:
 			// Poll every seconnd
@@ -426,6 +570,13 @@ func (s *Stack) PollStackEvents(stackName, waiterType string) error {
 	return nil
 }
 
+// Notify every sink that polling has finished.
+func dispatchComplete(sinks []EventSink, err error) {
+	for _, sink := range sinks {
+		sink.OnComplete(err)
+	}
+}
+
 // Get stack resources
 func (s *Stack) GetStackResources(stackName string) ([]*cf.StackResource, error) {
 	input := &cf.DescribeStackResourcesInput{
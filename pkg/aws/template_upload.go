@@ -0,0 +1,165 @@
+package aws
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager/s3manageriface"
+)
+
+// defaultPresignTTL is used when TemplateUploader.PresignTTL is unset.
+const defaultPresignTTL = time.Hour
+
+// TemplateUploader transparently moves oversized cloudformation templates
+// to S3 so they can be passed as a TemplateURL instead of an inline
+// TemplateBody, which CloudFormation caps at maxTemplateLength bytes.
+type TemplateUploader struct {
+	Client   s3iface.S3API
+	Uploader s3manageriface.UploaderAPI
+
+	Bucket   string
+	Prefix   string
+	KmsKeyId string
+
+	// PresignTTL controls how long the TemplateURL handed back to
+	// CloudFormation stays valid. Defaults to defaultPresignTTL.
+	PresignTTL time.Duration
+}
+
+// TemplateUploader constructor
+func NewTemplateUploader(sess *session.Session, bucket, prefix, kmsKeyId string) *TemplateUploader {
+	client := s3.New(sess)
+
+	return &TemplateUploader{
+		Client:   client,
+		Uploader: s3manager.NewUploaderWithClient(client),
+		Bucket:   bucket,
+		Prefix:   prefix,
+		KmsKeyId: kmsKeyId,
+	}
+}
+
+// templateKeyBasename matches the content-addressed basename TemplateKey
+// generates: a sha256 hex digest plus the ".json" suffix.
+var templateKeyBasename = regexp.MustCompile(`^[0-9a-f]{64}\.json$`)
+
+// TemplateKey returns the content-addressed S3 key for tpl, so repeated
+// uploads of the same template are idempotent no-ops.
+func (u *TemplateUploader) TemplateKey(tpl []byte) string {
+	sum := sha256.Sum256(tpl)
+	key := hex.EncodeToString(sum[:]) + ".json"
+
+	if len(u.Prefix) > 0 {
+		return strings.TrimSuffix(u.Prefix, "/") + "/" + key
+	}
+
+	return key
+}
+
+// ownsKey reports whether key looks like one TemplateKey would have
+// generated, so CleanupStale only ever touches objects cfctl itself put
+// in the bucket rather than unrelated neighbours sharing it.
+func (u *TemplateUploader) ownsKey(key string) bool {
+	if prefix := strings.TrimSuffix(u.Prefix, "/"); len(prefix) > 0 {
+		prefix += "/"
+		if !strings.HasPrefix(key, prefix) {
+			return false
+		}
+
+		key = strings.TrimPrefix(key, prefix)
+	}
+
+	return templateKeyBasename.MatchString(key)
+}
+
+// Upload puts tpl at its content-addressed key, skipping the upload if
+// it's already there, and returns the URL CloudFormation can use as a
+// TemplateURL.
+func (u *TemplateUploader) Upload(tpl []byte) (string, error) {
+	key := u.TemplateKey(tpl)
+
+	_, err := u.Client.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(u.Bucket),
+		Key:    aws.String(key),
+	})
+
+	if err != nil {
+		input := &s3manager.UploadInput{
+			Bucket: aws.String(u.Bucket),
+			Key:    aws.String(key),
+			Body:   bytes.NewReader(tpl),
+		}
+
+		if len(u.KmsKeyId) > 0 {
+			input.ServerSideEncryption = aws.String(s3.ServerSideEncryptionAwsKms)
+			input.SSEKMSKeyId = aws.String(u.KmsKeyId)
+		}
+
+		if _, err := u.Uploader.Upload(input); err != nil {
+			return "", err
+		}
+	}
+
+	ttl := u.PresignTTL
+	if ttl <= 0 {
+		ttl = defaultPresignTTL
+	}
+
+	req, _ := u.Client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(u.Bucket),
+		Key:    aws.String(key),
+	})
+
+	return req.Presign(ttl)
+}
+
+// CleanupStale deletes cfctl-owned objects under Prefix whose
+// last-modified time is older than ttl, so one-off diff/change-set
+// uploads don't accumulate in the bucket forever. Objects that don't
+// match TemplateKey's content-addressed naming are left alone, since
+// Prefix is commonly empty and the bucket may hold unrelated objects.
+func (u *TemplateUploader) CleanupStale(ttl time.Duration) error {
+	return u.cleanupStaleBefore(time.Now().Add(-ttl))
+}
+
+func (u *TemplateUploader) cleanupStaleBefore(cutoff time.Time) error {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(u.Bucket),
+		Prefix: aws.String(u.Prefix),
+	}
+
+	var stale []*s3.ObjectIdentifier
+	err := u.Client.ListObjectsV2Pages(input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			key := aws.StringValue(obj.Key)
+			if obj.LastModified != nil && obj.LastModified.Before(cutoff) && u.ownsKey(key) {
+				stale = append(stale, &s3.ObjectIdentifier{Key: obj.Key})
+			}
+		}
+
+		return true
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(stale) == 0 {
+		return nil
+	}
+
+	_, err = u.Client.DeleteObjects(&s3.DeleteObjectsInput{
+		Bucket: aws.String(u.Bucket),
+		Delete: &s3.Delete{Objects: stale},
+	})
+
+	return err
+}
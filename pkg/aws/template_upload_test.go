@@ -0,0 +1,228 @@
+package aws
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+)
+
+// stubS3Client embeds a real, network-free *s3.S3 so GetObjectRequest's
+// Presign keeps working (it only signs locally), while the methods
+// TemplateUploader actually exercises are overridden with canned
+// behaviour.
+type stubS3Client struct {
+	*s3.S3
+
+	headErr error
+
+	listObjects []*s3.Object
+	listErr     error
+
+	deletedKeys []string
+	deleteErr   error
+}
+
+func newStubS3Client() *stubS3Client {
+	sess := session.Must(session.NewSession(&aws.Config{
+		Region:      aws.String("ap-southeast-2"),
+		Credentials: credentials.NewStaticCredentials("akid", "secret", ""),
+	}))
+
+	return &stubS3Client{S3: s3.New(sess)}
+}
+
+func (c *stubS3Client) HeadObject(*s3.HeadObjectInput) (*s3.HeadObjectOutput, error) {
+	if c.headErr != nil {
+		return nil, c.headErr
+	}
+
+	return &s3.HeadObjectOutput{}, nil
+}
+
+func (c *stubS3Client) ListObjectsV2Pages(input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool) error {
+	if c.listErr != nil {
+		return c.listErr
+	}
+
+	fn(&s3.ListObjectsV2Output{Contents: c.listObjects}, true)
+
+	return nil
+}
+
+func (c *stubS3Client) DeleteObjects(input *s3.DeleteObjectsInput) (*s3.DeleteObjectsOutput, error) {
+	for _, obj := range input.Delete.Objects {
+		c.deletedKeys = append(c.deletedKeys, aws.StringValue(obj.Key))
+	}
+
+	return &s3.DeleteObjectsOutput{}, c.deleteErr
+}
+
+// stubUploader stands in for s3manageriface.UploaderAPI, recording
+// whether Upload was called without doing any real network work.
+type stubUploader struct {
+	calls int
+	err   error
+}
+
+func (u *stubUploader) Upload(input *s3manager.UploadInput, opts ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	u.calls++
+	if u.err != nil {
+		return nil, u.err
+	}
+
+	return &s3manager.UploadOutput{}, nil
+}
+
+func (u *stubUploader) UploadWithContext(ctx aws.Context, input *s3manager.UploadInput, opts ...func(*s3manager.Uploader)) (*s3manager.UploadOutput, error) {
+	return u.Upload(input, opts...)
+}
+
+func (u *stubUploader) UploadWithIterator(aws.Context, s3manager.BatchUploadIterator, ...func(*s3manager.Uploader)) error {
+	return nil
+}
+
+func newTestUploader(client *stubS3Client, uploader *stubUploader) *TemplateUploader {
+	return &TemplateUploader{
+		Client:   client,
+		Uploader: uploader,
+		Bucket:   "my-templates",
+		Prefix:   "cfctl",
+	}
+}
+
+func TestTemplateKey(t *testing.T) {
+	u := newTestUploader(newStubS3Client(), &stubUploader{})
+
+	tpl := []byte(`{"Resources":{}}`)
+
+	key := u.TemplateKey(tpl)
+	if !strings.HasPrefix(key, "cfctl/") || !strings.HasSuffix(key, ".json") {
+		t.Fatalf("unexpected key %q", key)
+	}
+
+	if u.TemplateKey(tpl) != key {
+		t.Fatalf("TemplateKey is not deterministic for the same content")
+	}
+}
+
+func TestUploadSkipsWhenObjectExists(t *testing.T) {
+	client := newStubS3Client()
+	uploader := &stubUploader{}
+	u := newTestUploader(client, uploader)
+
+	url, err := u.Upload([]byte(`{"Resources":{}}`))
+	if err != nil {
+		t.Fatalf("Upload returned error: %s", err)
+	}
+
+	if uploader.calls != 0 {
+		t.Fatalf("expected Upload to be skipped when HeadObject succeeds, got %d calls", uploader.calls)
+	}
+
+	if !strings.Contains(url, "my-templates") || !strings.Contains(url, "X-Amz-Signature") {
+		t.Fatalf("expected a presigned URL for the bucket, got %q", url)
+	}
+}
+
+func TestUploadUploadsWhenMissing(t *testing.T) {
+	client := newStubS3Client()
+	client.headErr = errors.New("NotFound")
+	uploader := &stubUploader{}
+	u := newTestUploader(client, uploader)
+
+	if _, err := u.Upload([]byte(`{"Resources":{}}`)); err != nil {
+		t.Fatalf("Upload returned error: %s", err)
+	}
+
+	if uploader.calls != 1 {
+		t.Fatalf("expected Upload to be called once, got %d calls", uploader.calls)
+	}
+}
+
+func TestUploadReturnsPresignedURL(t *testing.T) {
+	client := newStubS3Client()
+	u := newTestUploader(client, &stubUploader{})
+	u.PresignTTL = 5 * time.Minute
+
+	url, err := u.Upload([]byte(`{"Resources":{}}`))
+	if err != nil {
+		t.Fatalf("Upload returned error: %s", err)
+	}
+
+	if !strings.Contains(url, "X-Amz-Expires=300") {
+		t.Fatalf("expected the presigned URL to honour PresignTTL, got %q", url)
+	}
+}
+
+func TestCleanupStaleDeletesOnlyObjectsPastTTL(t *testing.T) {
+	now := time.Now()
+	stale := now.Add(-48 * time.Hour)
+	fresh := now.Add(-1 * time.Hour)
+
+	u := newTestUploader(newStubS3Client(), &stubUploader{})
+	staleKey := u.TemplateKey([]byte("stale template"))
+	freshKey := u.TemplateKey([]byte("fresh template"))
+
+	u.Client.(*stubS3Client).listObjects = []*s3.Object{
+		{Key: aws.String(staleKey), LastModified: &stale},
+		{Key: aws.String(freshKey), LastModified: &fresh},
+	}
+
+	if err := u.cleanupStaleBefore(now.Add(-24 * time.Hour)); err != nil {
+		t.Fatalf("cleanupStaleBefore returned error: %s", err)
+	}
+
+	deleted := u.Client.(*stubS3Client).deletedKeys
+	if len(deleted) != 1 || deleted[0] != staleKey {
+		t.Fatalf("expected only the stale key to be deleted, got %v", deleted)
+	}
+}
+
+func TestCleanupStaleNoOpWhenNothingStale(t *testing.T) {
+	now := time.Now()
+	fresh := now.Add(-1 * time.Hour)
+
+	u := newTestUploader(newStubS3Client(), &stubUploader{})
+	freshKey := u.TemplateKey([]byte("fresh template"))
+
+	u.Client.(*stubS3Client).listObjects = []*s3.Object{
+		{Key: aws.String(freshKey), LastModified: &fresh},
+	}
+
+	if err := u.cleanupStaleBefore(now.Add(-24 * time.Hour)); err != nil {
+		t.Fatalf("cleanupStaleBefore returned error: %s", err)
+	}
+
+	if deleted := u.Client.(*stubS3Client).deletedKeys; len(deleted) != 0 {
+		t.Fatalf("expected no deletions, got %v", deleted)
+	}
+}
+
+func TestCleanupStaleIgnoresObjectsNotOwnedByTemplateUploader(t *testing.T) {
+	now := time.Now()
+	stale := now.Add(-48 * time.Hour)
+
+	u := newTestUploader(newStubS3Client(), &stubUploader{})
+
+	// An unrelated object sharing the bucket (and even the prefix), but
+	// not shaped like a TemplateKey output, must never be swept up -
+	// the bucket is not assumed to be cfctl-exclusive.
+	u.Client.(*stubS3Client).listObjects = []*s3.Object{
+		{Key: aws.String("cfctl/some-other-teams-object.txt"), LastModified: &stale},
+	}
+
+	if err := u.cleanupStaleBefore(now.Add(-24 * time.Hour)); err != nil {
+		t.Fatalf("cleanupStaleBefore returned error: %s", err)
+	}
+
+	if deleted := u.Client.(*stubS3Client).deletedKeys; len(deleted) != 0 {
+		t.Fatalf("expected unrelated objects to be left alone, got %v", deleted)
+	}
+}
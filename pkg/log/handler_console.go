@@ -0,0 +1,67 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// ANSI color codes, one per level.
+const (
+	colorReset  = "\033[0m"
+	colorGray   = "\033[90m"
+	colorCyan   = "\033[36m"
+	colorYellow = "\033[33m"
+	colorRed    = "\033[31m"
+)
+
+// ConsoleHandler renders "[ level ] message" lines, colored per level
+// when w is a TTY and left plain otherwise (e.g. piped into a file or
+// log collector).
+type ConsoleHandler struct {
+	w      io.Writer
+	colors bool
+}
+
+// ConsoleHandler constructor
+func NewConsoleHandler(w io.Writer) *ConsoleHandler {
+	return &ConsoleHandler{w: w, colors: IsTerminal(w)}
+}
+
+func (h *ConsoleHandler) Handle(level Level, msg string) {
+	if !h.colors {
+		fmt.Fprintf(h.w, "[ %s ] %s\n", level, msg)
+		return
+	}
+
+	fmt.Fprintf(h.w, "%s[ %s ]%s %s\n", colorForLevel(level), level, colorReset, msg)
+}
+
+// IsTerminal reports whether w is a character device, i.e. an
+// interactive terminal rather than a redirected file or pipe.
+func IsTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+func colorForLevel(level Level) string {
+	switch level {
+	case LevelDebug:
+		return colorGray
+	case LevelWarn:
+		return colorYellow
+	case LevelError:
+		return colorRed
+	default:
+		return colorCyan
+	}
+}
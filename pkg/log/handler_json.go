@@ -0,0 +1,31 @@
+package log
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// JSONHandler writes one JSON object per log line, for machine
+// consumption (CI, log shippers).
+type JSONHandler struct {
+	w io.Writer
+}
+
+// JSONHandler constructor
+func NewJSONHandler(w io.Writer) *JSONHandler {
+	return &JSONHandler{w: w}
+}
+
+type jsonLine struct {
+	Level   string `json:"level"`
+	Message string `json:"message"`
+}
+
+func (h *JSONHandler) Handle(level Level, msg string) {
+	line, err := json.Marshal(jsonLine{Level: level.String(), Message: msg})
+	if err != nil {
+		return
+	}
+
+	h.w.Write(append(line, '\n'))
+}
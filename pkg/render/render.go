@@ -0,0 +1,58 @@
+// Package render pre-processes CloudFormation templates through a Go
+// text/template engine before they are handed to Stack.ValidateTemplate/
+// CreateStack/UpdateStack, so users can keep variables and encrypted
+// secrets out of the raw template and substitute them at deploy time.
+package render
+
+import (
+	"bytes"
+	"path/filepath"
+	"text/template"
+)
+
+// Engine renders a CloudFormation template with a fixed set of
+// variables and vault password resolution.
+type Engine struct {
+	Vars          map[string]interface{}
+	VaultPassword string
+	VaultPassFile string
+}
+
+// Engine constructor
+func NewEngine(vars map[string]interface{}, vaultPassword, vaultPassFile string) *Engine {
+	return &Engine{
+		Vars:          vars,
+		VaultPassword: vaultPassword,
+		VaultPassFile: vaultPassFile,
+	}
+}
+
+// Render parses templatePath together with any sibling "*.tpl" partials
+// in the same directory (so they can be referenced via
+// {{ template "partial-name" . }}) and executes it with the engine's
+// vars and the "vault" function.
+func (e *Engine) Render(templatePath string) ([]byte, error) {
+	dir := filepath.Dir(templatePath)
+
+	tmpl := template.New(filepath.Base(templatePath)).Funcs(template.FuncMap{
+		"vault": vaultFunc(e.VaultPassword, e.VaultPassFile),
+	})
+
+	partials, err := filepath.Glob(filepath.Join(dir, "*.tpl"))
+	if err != nil {
+		return nil, err
+	}
+
+	paths := append(partials, templatePath)
+	tmpl, err = tmpl.ParseFiles(paths...)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, filepath.Base(templatePath), e.Vars); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
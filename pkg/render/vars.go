@@ -0,0 +1,129 @@
+package render
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/liangrog/cfctl/pkg/utils"
+	"gopkg.in/yaml.v2"
+)
+
+// LoadVarsFile reads a YAML or JSON variable file, keyed by its
+// extension, into a generic map suitable for template rendering.
+func LoadVarsFile(path string) (map[string]interface{}, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make(map[string]interface{})
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		if err := json.Unmarshal(data, &vars); err != nil {
+			return nil, err
+		}
+	case ".yaml", ".yml":
+		if err := yaml.Unmarshal(data, &vars); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New(utils.MsgFormat(fmt.Sprintf("Unsupported variable file extension for %s, expected .yaml/.yml/.json", path), utils.MessageTypeError))
+	}
+
+	return normalizeYaml(vars), nil
+}
+
+// ParseSetFlags turns "key=value" pairs (as given via repeatable --set
+// flags) into a vars map. Dotted keys address nested maps, e.g.
+// "db.password=secret" sets vars["db"]["password"].
+func ParseSetFlags(sets []string) (map[string]interface{}, error) {
+	vars := make(map[string]interface{})
+
+	for _, set := range sets {
+		parts := strings.SplitN(set, "=", 2)
+		if len(parts) != 2 {
+			return nil, errors.New(utils.MsgFormat(fmt.Sprintf("Invalid --set value %q, expected key=value", set), utils.MessageTypeError))
+		}
+
+		setNested(vars, strings.Split(parts[0], "."), parts[1])
+	}
+
+	return vars, nil
+}
+
+// setNested assigns value at the nested path described by keys,
+// creating intermediate maps as needed.
+func setNested(vars map[string]interface{}, keys []string, value string) {
+	if len(keys) == 1 {
+		vars[keys[0]] = value
+		return
+	}
+
+	child, ok := vars[keys[0]].(map[string]interface{})
+	if !ok {
+		child = make(map[string]interface{})
+		vars[keys[0]] = child
+	}
+
+	setNested(child, keys[1:], value)
+}
+
+// Merge overlays override on top of base, recursing into nested maps so
+// a deep --set doesn't clobber its siblings.
+func Merge(base, override map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(base))
+	for k, v := range base {
+		out[k] = v
+	}
+
+	for k, v := range override {
+		if overrideMap, ok := v.(map[string]interface{}); ok {
+			if baseMap, ok := out[k].(map[string]interface{}); ok {
+				out[k] = Merge(baseMap, overrideMap)
+				continue
+			}
+		}
+
+		out[k] = v
+	}
+
+	return out
+}
+
+// yaml.v2 decodes nested maps as map[interface{}]interface{}, which
+// text/template can't index with a string key. Recursively normalize
+// those into map[string]interface{}.
+func normalizeYaml(in interface{}) map[string]interface{} {
+	out, _ := normalizeYamlValue(in).(map[string]interface{})
+	return out
+}
+
+func normalizeYamlValue(in interface{}) interface{} {
+	switch v := in.(type) {
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[fmt.Sprintf("%v", key)] = normalizeYamlValue(val)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			out[key] = normalizeYamlValue(val)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, val := range v {
+			out[i] = normalizeYamlValue(val)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,37 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/liangrog/cfctl/pkg/utils"
+	"github.com/liangrog/cfctl/pkg/vaultutil"
+	"github.com/liangrog/vault"
+)
+
+// vaultFunc returns the {{ vault "path/to/file" }} template function,
+// which transparently decrypts an ansible-vault encrypted file using the
+// same password resolution order as `cfctl vault decrypt`
+// (flag -> flag file -> env -> env file).
+func vaultFunc(pss, pssFile string) func(string) (string, error) {
+	return func(path string) (string, error) {
+		passwords, err := vaultutil.ResolvePasswords(pss, pssFile)
+		if err != nil {
+			return "", err
+		}
+
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+
+		for _, p := range passwords {
+			if output, err := vault.Decrypt(p, data); err == nil {
+				return string(output), nil
+			}
+		}
+
+		return "", errors.New(utils.MsgFormat(fmt.Sprintf("Failed to decrypt %s using all given vault passwords", path), utils.MessageTypeError))
+	}
+}
@@ -82,33 +82,9 @@ func Print(format FormatType, s ...interface{}) error {
 	return nil
 }
 
-// Format error message
+// Format error message, prefixing it with its message type so errors
+// are visually distinct from info/warn output.
 func MsgFormat(msg string, msgType MessageType, options ...string) string {
-	return fmt.Sprintf("%s", msg)
+	return fmt.Sprintf("[ %s ] %s", msgType, msg)
 }
 
-// Generic Print info.
-func InfoPrint(s ...interface{}) error {
-	return Print(FormatCmd, s...)
-}
-
-// Print to stdout with info header.
-func StdoutInfo(s ...interface{}) error {
-	s = append([]interface{}{fmt.Sprintf("[ %s ] ", MessageTypeInfo)}, s...)
-	_, err := fmt.Print(s...)
-	return err
-}
-
-// Print to stdout with warn header.
-func StdoutWarn(s ...interface{}) error {
-	s = append([]interface{}{fmt.Sprintf("[ %s ] ", MessageTypeWarn)}, s...)
-	_, err := fmt.Print(s...)
-	return err
-}
-
-// Print to stdout with error header.
-func StdoutError(s ...interface{}) error {
-	s = append([]interface{}{fmt.Sprintf("[ %s ] ", MessageTypeError)}, s...)
-	_, err := fmt.Print(s...)
-	return err
-}
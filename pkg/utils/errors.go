@@ -0,0 +1,56 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// AggregateError collects multiple errors encountered while processing a
+// batch of independent items (e.g. one per file) so the caller can surface
+// all of them instead of only the first.
+type AggregateError struct {
+	Errors []error
+}
+
+// Error implements the error interface, joining all collected errors
+// with a newline so each is visible on its own line in console output.
+func (e *AggregateError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, err := range e.Errors {
+		msgs[i] = err.Error()
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+// Combine collects the non-nil errors given into a single error. Returns
+// nil if none are non-nil, the error itself if there's exactly one, and
+// an *AggregateError otherwise.
+func Combine(errs ...error) error {
+	var nonNil []error
+	for _, err := range errs {
+		if err != nil {
+			nonNil = append(nonNil, err)
+		}
+	}
+
+	switch len(nonNil) {
+	case 0:
+		return nil
+	case 1:
+		return nonNil[0]
+	default:
+		return &AggregateError{Errors: nonNil}
+	}
+}
+
+// BatchResult tallies the outcome of a worker-pool batch run.
+type BatchResult struct {
+	Succeeded int
+	Failed    int
+}
+
+// String renders a one-line success/failed summary.
+func (r BatchResult) String() string {
+	return fmt.Sprintf("%d succeeded, %d failed", r.Succeeded, r.Failed)
+}
@@ -0,0 +1,26 @@
+package utils
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// WriteFileAtomic writes data to a temporary file alongside path and
+// renames it into place, so a crash or interrupt mid-write cannot leave
+// path truncated or corrupted.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := fmt.Sprintf("%s.tmp", path)
+
+	if err := ioutil.WriteFile(tmp, data, perm); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+// Package vaultutil resolves ansible-vault passwords for code that needs
+// to decrypt vault values outside of the `cfctl vault` commands, e.g. the
+// template renderer's `vault` function. `cfctl vault encrypt`/`decrypt`
+// do not call it: they resolve passwords through their own GetPasswords
+// helper.
+package vaultutil
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/liangrog/cfctl/pkg/utils"
+)
+
+const (
+	// EnvPassword is checked when no flag value or flag file is given.
+	EnvPassword = "CFCTL_VAULT_PASSWORD"
+
+	// EnvPasswordFile is checked when EnvPassword isn't set.
+	EnvPasswordFile = "CFCTL_VAULT_PASSWORD_FILE"
+)
+
+// ResolvePasswords returns the vault password(s) to try, in the same
+// order cfctl's vault commands use: explicit flag value, explicit flag
+// file, then the CFCTL_VAULT_PASSWORD/CFCTL_VAULT_PASSWORD_FILE
+// environment variables. Returns an error if none of them yield a
+// password.
+func ResolvePasswords(pss, pssFile string) ([]string, error) {
+	if len(pss) > 0 {
+		return []string{pss}, nil
+	}
+
+	if len(pssFile) > 0 {
+		return readPasswordFile(pssFile)
+	}
+
+	if env := os.Getenv(EnvPassword); len(env) > 0 {
+		return []string{env}, nil
+	}
+
+	if envFile := os.Getenv(EnvPasswordFile); len(envFile) > 0 {
+		return readPasswordFile(envFile)
+	}
+
+	return nil, errors.New(utils.MsgFormat("No vault password given via flag or CFCTL_VAULT_PASSWORD(_FILE)", utils.MessageTypeError))
+}
+
+// readPasswordFile reads one password per line, ignoring blank lines.
+func readPasswordFile(path string) ([]string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var passwords []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if len(line) > 0 {
+			passwords = append(passwords, line)
+		}
+	}
+
+	return passwords, nil
+}